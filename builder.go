@@ -0,0 +1,340 @@
+package main
+
+import "fmt"
+
+// xmlElem is the intermediate representation of a generated Go type: an
+// XML element resolved down to its fields, ready for doGenerate to emit.
+type xmlElem struct {
+	Name     string
+	Type     string
+	TypeName string
+	Attribs  []xmlAttrib
+	Children []*xmlElem
+	Cdata    bool
+	List     bool
+	Inline   bool
+	Optional bool
+	Enum     []string
+	EnumBase string
+	Temporal string
+}
+
+// xmlAttrib is a single XML attribute resolved to its Go type.
+type xmlAttrib struct {
+	Name     string
+	Type     string
+	Optional bool
+	Enum     []string
+	EnumBase string
+	Temporal string
+}
+
+var (
+	compact bool
+	types   = make(map[string]struct{})
+
+	// anyElement, anyElementType and anyElementEmitted back -any-element;
+	// see WithAnyElement and WithAnyElementTypeName.
+	anyElement        bool
+	anyElementType    = "AnyXMLElement"
+	anyElementEmitted bool
+
+	// temporalEmitted tracks whether the Date/DateTime/Time/Duration
+	// wrapper types (see generateTemporalTypes) have already been written
+	// to the current output.
+	temporalEmitted bool
+)
+
+// resetGenerationState clears the once-per-output bookkeeping doGenerate
+// relies on (the types map plus the any-element/temporal "already
+// emitted" flags), so a fresh file can be generated from scratch.
+func resetGenerationState() {
+	types = make(map[string]struct{})
+	anyElementEmitted = false
+	temporalEmitted = false
+}
+
+// WithAnyElement turns the -any-element catch-all field on or off for all
+// subsequent generation.
+func WithAnyElement(on bool) {
+	anyElement = on
+}
+
+// WithAnyElementTypeName sets the Go type name used for the -any-element
+// catch-all field, letting callers avoid collisions with their own types.
+func WithAnyElementTypeName(name string) {
+	anyElementType = name
+}
+
+// builder resolves a set of xsdSchema declarations into a tree of xmlElem
+// values.
+type builder struct {
+	schemas      []xsdSchema
+	complexTypes map[string]*xsdComplexType
+	simpleTypes  map[string]*xsdSimpleType
+	typeRefs     map[string]int
+}
+
+// newBuilder indexes the complexType/simpleType declarations across all
+// given schemas so that type references can be resolved regardless of
+// which schema declared them.
+//
+// The merged table is keyed by local name only, not by (namespace, name):
+// goxsd does not track each schema's targetNamespace, so two schemas
+// (typically reached via xs:import) that declare the same local type
+// name under different namespaces cannot both be represented. Rather
+// than silently letting the later schema's declaration win, that case is
+// reported as an error.
+func newBuilder(schemas []xsdSchema) (*builder, error) {
+	b := &builder{
+		schemas:      schemas,
+		complexTypes: make(map[string]*xsdComplexType),
+		simpleTypes:  make(map[string]*xsdSimpleType),
+		typeRefs:     make(map[string]int),
+	}
+	for _, s := range schemas {
+		for i := range s.ComplexTypes {
+			ct := s.ComplexTypes[i]
+			if _, dup := b.complexTypes[ct.Name]; dup {
+				return nil, fmt.Errorf("complexType %q is declared more than once across the loaded schemas (possibly under different namespaces, which goxsd does not distinguish)", ct.Name)
+			}
+			b.complexTypes[ct.Name] = &ct
+		}
+		for i := range s.SimpleTypes {
+			st := s.SimpleTypes[i]
+			if _, dup := b.simpleTypes[st.Name]; dup {
+				return nil, fmt.Errorf("simpleType %q is declared more than once across the loaded schemas (possibly under different namespaces, which goxsd does not distinguish)", st.Name)
+			}
+			b.simpleTypes[st.Name] = &st
+		}
+	}
+	return b, nil
+}
+
+// buildXML resolves every top-level element across the schemas into its
+// xmlElem tree, then annotates each node with whether its complexType is
+// only ever referenced from that one place (see Inline on xmlElem).
+func (b *builder) buildXML() []*xmlElem {
+	var elems []*xmlElem
+	for _, s := range b.schemas {
+		for _, e := range s.Elements {
+			elems = append(elems, b.buildElem(e))
+		}
+	}
+	for _, e := range elems {
+		b.annotateInline(e)
+	}
+	return elems
+}
+
+// annotateInline marks every element (and, recursively, its children)
+// whose complexType is anonymous or referenced by exactly one element as
+// eligible for inline emission in -compact mode.
+func (b *builder) annotateInline(e *xmlElem) {
+	e.Inline = e.TypeName == "" || b.typeRefs[e.TypeName] <= 1
+	for _, c := range e.Children {
+		b.annotateInline(c)
+	}
+}
+
+// buildElem resolves a single xsdElement, following its complexType (be it
+// inline or referenced by name) down to either a struct-shaped element
+// (one with child elements) or a leaf element (character data plus
+// attributes).
+func (b *builder) buildElem(e xsdElement) *xmlElem {
+	ct := e.ComplexType
+	if ct == nil && e.Type != "" {
+		ct = b.complexTypes[e.Type]
+		if ct != nil {
+			b.typeRefs[ct.Name]++
+		}
+	}
+
+	if ct != nil && len(ct.Sequence) > 0 {
+		elem := &xmlElem{
+			Name:     e.Name,
+			Type:     e.Name,
+			TypeName: ct.Name,
+			Attribs:  b.buildAttribs(ct.Attributes),
+		}
+		for _, child := range ct.Sequence {
+			c := b.buildElem(child)
+			c.List = child.list()
+			c.Optional = child.MinOccurs == "0"
+			elem.Children = append(elem.Children, c)
+		}
+		return elem
+	}
+
+	if ct != nil && ct.SimpleContent != nil {
+		base, attribs := b.resolveSimpleContent(ct.SimpleContent)
+		typ, enum, enumBase, temporal := b.resolveLeafType(base)
+		return &xmlElem{
+			Name:     e.Name,
+			Type:     typ,
+			TypeName: ct.Name,
+			Cdata:    true,
+			Attribs:  attribs,
+			Enum:     enum,
+			EnumBase: enumBase,
+			Temporal: temporal,
+		}
+	}
+
+	if name, base, values, ok := b.resolveInlineEnum(e.SimpleType, e.Name); ok {
+		return &xmlElem{
+			Name:     e.Name,
+			Type:     name,
+			Cdata:    true,
+			Optional: e.MinOccurs == "0",
+			Enum:     values,
+			EnumBase: base,
+		}
+	}
+
+	typ := e.Type
+	if e.SimpleType != nil && e.SimpleType.Restriction != nil {
+		typ = e.SimpleType.Restriction.Base
+	}
+	goTyp, enum, enumBase, temporal := b.resolveLeafType(typ)
+	return &xmlElem{
+		Name:     e.Name,
+		Type:     goTyp,
+		Cdata:    true,
+		Optional: e.MinOccurs == "0",
+		Enum:     enum,
+		EnumBase: enumBase,
+		Temporal: temporal,
+	}
+}
+
+// resolveSimpleContent follows a simpleContent's extension/restriction
+// chain down to its ultimate base type, collecting attributes declared
+// along the way (base-most attributes first, so the outermost extension's
+// own attributes are appended last).
+func (b *builder) resolveSimpleContent(sc *xsdSimpleContent) (string, []xmlAttrib) {
+	switch {
+	case sc.Extension != nil:
+		base := sc.Extension.Base
+		attribs := b.buildAttribs(sc.Extension.Attributes)
+		if inner := b.complexTypes[base]; inner != nil && inner.SimpleContent != nil {
+			innerBase, innerAttribs := b.resolveSimpleContent(inner.SimpleContent)
+			return innerBase, append(innerAttribs, attribs...)
+		}
+		return base, attribs
+	case sc.Restriction != nil:
+		base := sc.Restriction.Base
+		if inner := b.complexTypes[base]; inner != nil && inner.SimpleContent != nil {
+			return b.resolveSimpleContent(inner.SimpleContent)
+		}
+		return base, nil
+	default:
+		return "string", nil
+	}
+}
+
+func (b *builder) buildAttribs(attrs []xsdAttribute) []xmlAttrib {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]xmlAttrib, 0, len(attrs))
+	for _, a := range attrs {
+		if name, base, values, ok := b.resolveInlineEnum(a.SimpleType, a.Name); ok {
+			out = append(out, xmlAttrib{
+				Name:     a.Name,
+				Type:     name,
+				Optional: a.Use != "required",
+				Enum:     values,
+				EnumBase: base,
+			})
+			continue
+		}
+		typ, enum, enumBase, temporal := b.resolveLeafType(a.Type)
+		out = append(out, xmlAttrib{
+			Name:     a.Name,
+			Type:     typ,
+			Optional: a.Use != "required",
+			Enum:     enum,
+			EnumBase: enumBase,
+			Temporal: temporal,
+		})
+	}
+	return out
+}
+
+// resolveLeafType maps an xsd type name to the Go type used to represent
+// it. If t is a simpleType restricted to a fixed set of enumeration
+// values, the xsd type name itself is returned (for doGenerate to turn
+// into a named Go type) along with the enumerated values and their
+// underlying Go type. If t (or the simpleType it aliases) names a
+// temporal base type, the Go wrapper type for that kind is returned
+// along with the xsd kind itself (see temporalKind); otherwise t
+// resolves straight to a Go primitive.
+func (b *builder) resolveLeafType(t string) (typ string, enum []string, enumBase string, temporal string) {
+	if name, base, values, ok := b.resolveEnum(t); ok {
+		return name, values, base, ""
+	}
+	if kind, ok := temporalKind(b.resolveBase(t)); ok {
+		return temporalTypeName(kind), nil, "", kind
+	}
+	return b.goType(t), nil, "", ""
+}
+
+// resolveBase follows a chain of named simpleType aliases (simpleTypes
+// that merely restrict another type without adding an enumeration) down
+// to the ultimate base type name.
+func (b *builder) resolveBase(t string) string {
+	if st, ok := b.simpleTypes[t]; ok && st.Restriction != nil {
+		return b.resolveBase(st.Restriction.Base)
+	}
+	return t
+}
+
+// resolveEnum reports whether t names a simpleType restricted to a fixed
+// set of <xs:enumeration> values.
+func (b *builder) resolveEnum(t string) (name, base string, values []string, ok bool) {
+	return b.resolveInlineEnum(b.simpleTypes[t], "")
+}
+
+// resolveInlineEnum reports whether st (a simpleType already in hand,
+// typically an inline/anonymous one such as e.SimpleType or a.SimpleType)
+// is restricted to a fixed set of <xs:enumeration> values. Anonymous
+// simpleTypes have no name of their own, so fallback (by convention the
+// enclosing element's or attribute's name, mirroring how buildElem names
+// anonymous complexTypes after their element) is used as the generated
+// enum type's xsd name when st.Name is empty.
+func (b *builder) resolveInlineEnum(st *xsdSimpleType, fallback string) (name, base string, values []string, ok bool) {
+	if st == nil || st.Restriction == nil || len(st.Restriction.Enumeration) == 0 {
+		return "", "", nil, false
+	}
+	values = make([]string, len(st.Restriction.Enumeration))
+	for i, en := range st.Restriction.Enumeration {
+		values[i] = en.Value
+	}
+	name = st.Name
+	if name == "" {
+		name = fallback
+	}
+	return name, b.goType(st.Restriction.Base), values, true
+}
+
+// goType maps an xsd type name, which may be a primitive or a reference
+// to a locally declared simpleType, to its Go equivalent.
+func (b *builder) goType(t string) string {
+	if st, ok := b.simpleTypes[t]; ok {
+		if st.Restriction != nil {
+			return b.goType(st.Restriction.Base)
+		}
+		return "string"
+	}
+	switch t {
+	case "boolean":
+		return "bool"
+	case "int", "integer", "short", "long", "positiveInteger", "nonNegativeInteger":
+		return "int"
+	case "float", "double", "decimal":
+		return "float64"
+	default:
+		return "string"
+	}
+}
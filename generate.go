@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// doGenerate writes the Go source for root and every struct-shaped or
+// enumerated descendant it references, skipping any type already emitted
+// for this output (tracked via the package-level types map, which callers
+// generating multiple root elements into the same output share across
+// calls; see resetGenerationState).
+func doGenerate(root *xmlElem, out io.Writer) {
+	generateStruct(root, out)
+	if anyElement {
+		generateAnyElementType(out)
+	}
+	if usesTemporal(root) {
+		generateTemporalTypes(out)
+	}
+}
+
+// isComplex reports whether e needs its own struct representation, as
+// opposed to being inlined as a plain field on its parent.
+func isComplex(e *xmlElem) bool {
+	return len(e.Children) > 0 || len(e.Attribs) > 0
+}
+
+// enumRef records an enumerated field so its type and const block can be
+// emitted once the struct referencing it is complete.
+type enumRef struct {
+	xsdName string
+	base    string
+	values  []string
+}
+
+func generateStruct(e *xmlElem, out io.Writer) {
+	name := goName(e.Name)
+	if _, ok := types[name]; ok {
+		return
+	}
+	types[name] = struct{}{}
+
+	fmt.Fprintf(out, "\n// %s was generated from the %q element.\ntype %s struct {\n", name, e.Name, name)
+	enums, pending := writeFields(e, out, "")
+	if anyElement && !e.Cdata {
+		fmt.Fprintf(out, "\tAnyElements []%s `xml:\",any\"`\n", anyElementType)
+	}
+	fmt.Fprintf(out, "}\n")
+
+	for _, en := range enums {
+		generateEnum(en.xsdName, en.base, en.values, out)
+	}
+	for _, c := range pending {
+		generateStruct(c, out)
+	}
+}
+
+// writeFields writes e's attribute, child and chardata field declarations
+// at the given indent. Children whose complexType is only ever referenced
+// by that one element are, in -compact mode, inlined as an anonymous
+// struct rather than deferred to their own named type; writeFields
+// recurses into those directly so their fields land inline too. It
+// returns the enum types and the named struct types (isComplex children
+// that were not inlined) the caller still needs to emit.
+func writeFields(e *xmlElem, out io.Writer, indent string) (enums []enumRef, pending []*xmlElem) {
+	for _, a := range e.Attribs {
+		typ := a.Type
+		if len(a.Enum) > 0 {
+			typ = enumName(a.Type)
+			enums = append(enums, enumRef{a.Type, a.EnumBase, a.Enum})
+		}
+		tag := a.Name + ",attr"
+		if a.Temporal != "" && a.Optional {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(out, "%s\t%s %s `xml:\"%s\"`\n", indent, attrName(a.Name), typ, tag)
+	}
+
+	for _, c := range e.Children {
+		if isComplex(c) && compact && c.Inline {
+			open := "struct {\n"
+			if c.List {
+				open = "[]struct {\n"
+			}
+			fmt.Fprintf(out, "%s\t%s %s", indent, fieldName(c.Name), open)
+			childEnums, childPending := writeFields(c, out, indent+"\t")
+			enums = append(enums, childEnums...)
+			pending = append(pending, childPending...)
+			if anyElement && !c.Cdata {
+				fmt.Fprintf(out, "%s\t\tAnyElements []%s `xml:\",any\"`\n", indent, anyElementType)
+			}
+			fmt.Fprintf(out, "%s\t} `xml:\"%s\"`\n", indent, c.Name)
+			continue
+		}
+
+		typ := c.Type
+		switch {
+		case isComplex(c):
+			typ = goName(c.Name)
+			pending = append(pending, c)
+		case len(c.Enum) > 0:
+			typ = enumName(c.Type)
+			enums = append(enums, enumRef{c.Type, c.EnumBase, c.Enum})
+		}
+		if c.List {
+			typ = "[]" + typ
+		}
+		tag := c.Name
+		if c.Temporal != "" && c.Optional && !c.List {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(out, "%s\t%s %s `xml:\"%s\"`\n", indent, fieldName(c.Name), typ, tag)
+	}
+
+	if e.Cdata {
+		typ := e.Type
+		if len(e.Enum) > 0 {
+			typ = enumName(e.Type)
+			enums = append(enums, enumRef{e.Type, e.EnumBase, e.Enum})
+		}
+		fmt.Fprintf(out, "%s\t%s %s `xml:\",chardata\"`\n", indent, fieldName(e.Name), typ)
+	}
+
+	return enums, pending
+}
+
+// generateEnum emits a named Go type plus a const block listing its
+// allowed values, skipping the type if it was already emitted for this
+// output.
+func generateEnum(xsdName, base string, values []string, out io.Writer) {
+	name := enumName(xsdName)
+	if _, ok := types[name]; ok {
+		return
+	}
+	types[name] = struct{}{}
+
+	fmt.Fprintf(out, "\n// %s was generated from the %q simpleType.\ntype %s %s\n\nconst (\n", name, xsdName, name, base)
+	for _, v := range values {
+		lit := v
+		if base == "string" {
+			lit = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(out, "\t%s%s %s = %s\n", name, identFromValue(v), name, lit)
+	}
+	fmt.Fprintf(out, ")\n")
+}
+
+// generateAnyElementType emits the shared catch-all type referenced by
+// every struct's AnyElements field, once per output (main.go is
+// responsible for resetting anyElementEmitted, along with the
+// per-struct/enum types map, before generating a fresh file).
+func generateAnyElementType(out io.Writer) {
+	if anyElementEmitted {
+		return
+	}
+	anyElementEmitted = true
+
+	fmt.Fprintf(out, `
+// %s is a catch-all for elements not declared in the schema.
+type %s struct {
+	XMLName  xml.Name   `+"`xml:\",any\"`"+`
+	Attrs    []xml.Attr `+"`xml:\",any,attr\"`"+`
+	Chardata string     `+"`xml:\",chardata\"`"+`
+	Children []%s       `+"`xml:\",any\"`"+`
+}
+`, anyElementType, anyElementType, anyElementType)
+}
+
+// generateTemporalTypes emits the shared Date/DateTime/Time/Duration
+// wrapper types referenced by any temporal field (see usesTemporal), once
+// per output.
+func generateTemporalTypes(out io.Writer) {
+	if temporalEmitted {
+		return
+	}
+	temporalEmitted = true
+
+	io.WriteString(out, temporalTypesSource)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadSchemas parses the XSD document at path and recursively follows any
+// <xs:include>/<xs:import> schemaLocation it declares, resolving each one
+// relative to the including file's directory or, failing that, to one of
+// searchPath. Every resolved file is parsed at most once, so a schema
+// included from multiple places only contributes its declarations a
+// single time.
+func loadSchemas(path string, searchPath []string) ([]xsdSchema, error) {
+	var schemas []xsdSchema
+	seen := make(map[string]bool)
+	if err := loadSchema(path, searchPath, seen, &schemas); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func loadSchema(path string, searchPath []string, seen map[string]bool, schemas *[]xsdSchema) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if seen[abs] {
+		return nil
+	}
+	seen[abs] = true
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	schema, err := extract(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	*schemas = append(*schemas, schema)
+
+	dir := filepath.Dir(abs)
+	for _, inc := range schema.Includes {
+		ref, err := resolveSchemaLocation(inc.SchemaLocation, dir, searchPath)
+		if err != nil {
+			return err
+		}
+		if err := loadSchema(ref, searchPath, seen, schemas); err != nil {
+			return err
+		}
+	}
+	for _, imp := range schema.Imports {
+		if imp.SchemaLocation == "" {
+			continue
+		}
+		ref, err := resolveSchemaLocation(imp.SchemaLocation, dir, searchPath)
+		if err != nil {
+			return err
+		}
+		if err := loadSchema(ref, searchPath, seen, schemas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSchemaLocation finds the file a schemaLocation attribute refers
+// to, trying it relative to dir (the including file's directory) before
+// falling through searchPath in order.
+func resolveSchemaLocation(loc, dir string, searchPath []string) (string, error) {
+	candidates := make([]string, 0, len(searchPath)+1)
+	candidates = append(candidates, filepath.Join(dir, loc))
+	for _, sp := range searchPath {
+		candidates = append(candidates, filepath.Join(sp, loc))
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("schemaLocation %q: not found (searched %v)", loc, candidates)
+}
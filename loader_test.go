@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSchemasFollowsInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	base := `<schema>
+	<include schemaLocation="tag.xsd" />
+	<element name="tag" type="tagReferenceType" />
+</schema>`
+	included := `<schema>
+	<complexType name="tagReferenceType">
+		<simpleContent>
+			<extension base="string">
+				<attribute name="type" type="string" use="required" />
+			</extension>
+		</simpleContent>
+	</complexType>
+</schema>`
+
+	basePath := filepath.Join(dir, "base.xsd")
+	if err := os.WriteFile(basePath, []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tag.xsd"), []byte(included), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := loadSchemas(basePath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("got %d schemas, want 2", len(schemas))
+	}
+
+	b, err := newBuilder(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elems := b.buildXML()
+	if len(elems) != 1 {
+		t.Fatalf("got %d elements, want 1", len(elems))
+	}
+
+	e := elems[0]
+	if e.Name != "tag" || e.Type != "string" || !e.Cdata {
+		t.Errorf("unexpected element resolved across files: %+v", e)
+	}
+	if len(e.Attribs) != 1 || e.Attribs[0].Name != "type" {
+		t.Errorf("expected type resolved from included schema, got %+v", e.Attribs)
+	}
+}
+
+func TestLoadSchemasDedupesRepeatedInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	a := `<schema>
+	<include schemaLocation="common.xsd" />
+</schema>`
+	common := `<schema>
+	<simpleType name="nidType">
+		<restriction base="string" />
+	</simpleType>
+</schema>`
+
+	if err := os.WriteFile(filepath.Join(dir, "a.xsd"), []byte(a), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "common.xsd"), []byte(common), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := loadSchemas(filepath.Join(dir, "a.xsd"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("got %d schemas, want 2", len(schemas))
+	}
+}
+
+func TestLoadSchemasResolvesImportViaSearchPath(t *testing.T) {
+	dir := t.TempDir()
+	vendor := t.TempDir()
+
+	base := `<schema>
+	<import namespace="urn:example" schemaLocation="vendor.xsd" />
+	<element name="tag" type="tagReferenceType" />
+</schema>`
+	imported := `<schema>
+	<complexType name="tagReferenceType">
+		<simpleContent>
+			<extension base="string" />
+		</simpleContent>
+	</complexType>
+</schema>`
+
+	basePath := filepath.Join(dir, "base.xsd")
+	if err := os.WriteFile(basePath, []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vendor, "vendor.xsd"), []byte(imported), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := loadSchemas(basePath, []string{vendor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("got %d schemas, want 2", len(schemas))
+	}
+}
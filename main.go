@@ -0,0 +1,121 @@
+// Command goxsd generates Go struct definitions from an XSD schema.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// parseNameFunc resolves the -name-func flag value to a NameFunc:
+// "lowercase" (the default) and "export" select the built-in functions of
+// the same name, and "prefix:Xxx" selects PrefixNameFunc("Xxx").
+func parseNameFunc(s string) (NameFunc, error) {
+	switch {
+	case s == "" || s == "lowercase":
+		return LowercaseNameFunc, nil
+	case s == "export":
+		return ExportedNameFunc, nil
+	case s == "camel":
+		return CamelNameFunc, nil
+	case strings.HasPrefix(s, "prefix:"):
+		return PrefixNameFunc(strings.TrimPrefix(s, "prefix:")), nil
+	default:
+		return nil, fmt.Errorf("unknown -name-func %q (want lowercase, export, camel or prefix:Xxx)", s)
+	}
+}
+
+// searchPath collects repeated -I flags into a list of directories to try
+// when resolving xs:include/xs:import schemaLocation attributes.
+type searchPath []string
+
+func (s *searchPath) String() string { return strings.Join(*s, ",") }
+
+func (s *searchPath) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func main() {
+	nameFuncFlag := flag.String("name-func", "lowercase", "identifier naming: lowercase, export, camel, or prefix:Xxx")
+	flag.BoolVar(&compact, "compact", false, "inline complexTypes referenced by exactly one element")
+	anyElementFlag := flag.Bool("any-element", false, `append a catch-all AnyElements field (xml:",any") to every generated struct`)
+	anyElementTypeFlag := flag.String("any-element-type", anyElementType, "Go type name for the -any-element catch-all")
+	out := flag.String("o", "", "output file (defaults to stdout)")
+	var include searchPath
+	flag.Var(&include, "I", "additional search path for xs:include/xs:import schemaLocation (repeatable)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: goxsd [-name-func lowercase|export|camel|prefix:Xxx] [-I dir] [-o file] schema.xsd")
+		os.Exit(1)
+	}
+
+	fn, err := parseNameFunc(*nameFuncFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	WithNameFunc(fn)
+	WithAnyElement(*anyElementFlag)
+	WithAnyElementTypeName(*anyElementTypeFlag)
+
+	schemas, err := loadSchemas(flag.Arg(0), include)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	b, err := newBuilder(schemas)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	elems := b.buildXML()
+
+	needsTemporal := false
+	for _, e := range elems {
+		if usesTemporal(e) {
+			needsTemporal = true
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "package main")
+	switch {
+	case needsTemporal:
+		fmt.Fprintln(&buf, `import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)`)
+	case anyElement:
+		fmt.Fprintln(&buf, `import "encoding/xml"`)
+	}
+
+	resetGenerationState()
+	for _, e := range elems {
+		doGenerate(e, &buf)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import "unicode"
+
+// commonInitialisms lists the acronyms we upper-case in generated
+// identifiers, mirroring golint's list closely enough for the names that
+// show up in real-world schemas.
+var commonInitialisms = map[string]bool{
+	"ID":   true,
+	"URL":  true,
+	"URI":  true,
+	"HTTP": true,
+	"API":  true,
+	"XML":  true,
+	"JSON": true,
+	"UUID": true,
+}
+
+// NameKind identifies the role an xsd identifier plays, so a NameFunc can
+// apply role-specific conventions, e.g. always exporting struct fields
+// regardless of how it names types.
+type NameKind int
+
+const (
+	// NameType names a generated struct or enum type.
+	NameType NameKind = iota
+	// NameField names a plain struct field (an element or chardata).
+	NameField
+	// NameAttr names a struct field generated from an xsd attribute.
+	NameAttr
+	// NameEnum names the Go type backing an xsd enumeration.
+	NameEnum
+)
+
+// NameFunc maps an xsd identifier to the Go identifier used for it in
+// generated source.
+type NameFunc func(xsdName string, kind NameKind) string
+
+// nameFunc is the NameFunc used by the builder and generator; it defaults
+// to LowercaseNameFunc, matching goxsd's original unexported, unprefixed
+// output. Set it with WithNameFunc.
+var nameFunc NameFunc = LowercaseNameFunc
+
+// WithNameFunc installs fn as the NameFunc used for all subsequent name
+// generation, letting downstream tools supply their own identifier
+// mapping (e.g. to dodge collisions with Go keywords or to match a house
+// style guide).
+func WithNameFunc(fn NameFunc) {
+	nameFunc = fn
+}
+
+// LowercaseNameFunc reproduces goxsd's original default: struct and enum
+// types keep the xsd identifier's own case (so a lowercase xsd name
+// stays unexported), while fields and attributes are always exported
+// since encoding/xml cannot populate unexported struct fields.
+func LowercaseNameFunc(xsdName string, kind NameKind) string {
+	n := fixInitialisms(xsdName)
+	if kind == NameType || kind == NameEnum {
+		return n
+	}
+	return export(n)
+}
+
+// ExportedNameFunc exports every identifier it names, reproducing
+// goxsd's original -e flag.
+func ExportedNameFunc(xsdName string, kind NameKind) string {
+	return export(fixInitialisms(xsdName))
+}
+
+// PrefixNameFunc returns a NameFunc that exports every identifier and
+// additionally prefixes generated struct and enum type names with
+// prefix, reproducing goxsd's original -p flag.
+func PrefixNameFunc(prefix string) NameFunc {
+	p := export(prefix)
+	return func(xsdName string, kind NameKind) string {
+		n := export(fixInitialisms(xsdName))
+		if (kind == NameType || kind == NameEnum) && p != "" {
+			return p + n
+		}
+		return n
+	}
+}
+
+// CamelNameFunc exports every identifier, fixes common initialisms, and
+// additionally treats '_' and '-' as word boundaries so that snake_case
+// and kebab-case xsd names come out as proper Go CamelCase (e.g.
+// "tag_id" and "tagId" both become "TagID", rather than the field/type
+// collision a naive concatenation of name and type produces).
+func CamelNameFunc(xsdName string, kind NameKind) string {
+	return export(fixInitialisms(camelize(xsdName)))
+}
+
+// camelize splits s on '_' and '-' in addition to case boundaries and
+// exports each resulting word.
+func camelize(s string) string {
+	var words []string
+	var cur []rune
+	for _, r := range s {
+		if r == '_' || r == '-' {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	for i, w := range words {
+		words[i] = export(w)
+	}
+	return join(words)
+}
+
+// splitWords breaks a camelCase or PascalCase identifier into its
+// constituent words.
+func splitWords(name string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(runes[i-1]) {
+			words = append(words, string(cur))
+			cur = nil
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+// fixInitialisms upper-cases any word in name that is a known initialism,
+// e.g. "tagId" becomes "tagID".
+func fixInitialisms(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		if up := upper(w); commonInitialisms[up] {
+			words[i] = up
+		}
+	}
+	return join(words)
+}
+
+func upper(s string) string {
+	r := []rune(s)
+	for i, c := range r {
+		r[i] = unicode.ToUpper(c)
+	}
+	return string(r)
+}
+
+func join(words []string) string {
+	out := ""
+	for _, w := range words {
+		out += w
+	}
+	return out
+}
+
+// export upper-cases the first rune of s, as required for a field or type
+// to be visible to encoding/xml.
+func export(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// goName derives the Go type name for an xsd identifier using the
+// installed NameFunc.
+func goName(name string) string {
+	return nameFunc(name, NameType)
+}
+
+// fieldName derives the Go struct field name for a plain xsd element
+// using the installed NameFunc.
+func fieldName(name string) string {
+	return nameFunc(name, NameField)
+}
+
+// attrName derives the Go struct field name for an xsd attribute using
+// the installed NameFunc.
+func attrName(name string) string {
+	return nameFunc(name, NameAttr)
+}
+
+// enumName derives the Go type name for an xsd enumeration using the
+// installed NameFunc.
+func enumName(name string) string {
+	return nameFunc(name, NameEnum)
+}
+
+// identFromValue turns an xs:enumeration value into an exported Go
+// identifier suffix, e.g. "in-progress" becomes "InProgress".
+func identFromValue(v string) string {
+	var out []rune
+	upperNext := true
+	for _, r := range v {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upperNext {
+				r = unicode.ToUpper(r)
+				upperNext = false
+			}
+			out = append(out, r)
+		default:
+			upperNext = true
+		}
+	}
+	return string(out)
+}
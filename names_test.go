@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestCamelNameFunc(t *testing.T) {
+	tests := []struct {
+		xsdName string
+		kind    NameKind
+		want    string
+	}{
+		{"tag_id", NameType, "TagID"},
+		{"tagId", NameField, "TagID"},
+		{"tag-id", NameAttr, "TagID"},
+		{"book_title", NameType, "BookTitle"},
+		{"url", NameEnum, "URL"},
+	}
+	for _, tst := range tests {
+		if got := CamelNameFunc(tst.xsdName, tst.kind); got != tst.want {
+			t.Errorf("CamelNameFunc(%q, %v) = %q, want %q", tst.xsdName, tst.kind, got, tst.want)
+		}
+	}
+}
+
+func TestCamelize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"tag_id", "TagId"},
+		{"tag-id", "TagId"},
+		{"tagId", "TagId"},
+		{"book_title_text", "BookTitleText"},
+	}
+	for _, tst := range tests {
+		if got := camelize(tst.in); got != tst.want {
+			t.Errorf("camelize(%q) = %q, want %q", tst.in, got, tst.want)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"tagId", []string{"tag", "Id"}},
+		{"TagID", []string{"Tag", "ID"}},
+		{"book", []string{"book"}},
+	}
+	for _, tst := range tests {
+		got := splitWords(tst.in)
+		if len(got) != len(tst.want) {
+			t.Fatalf("splitWords(%q) = %v, want %v", tst.in, got, tst.want)
+		}
+		for i := range got {
+			if got[i] != tst.want[i] {
+				t.Errorf("splitWords(%q) = %v, want %v", tst.in, got, tst.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import "fmt"
+
+// temporalKind reports whether base names one of the xsd temporal
+// primitives goxsd knows how to map to a typed Go field, returning the
+// kind used to pick both the Go wrapper type (see temporalTypeName) and
+// its marshaling code (see generateTemporalTypes).
+func temporalKind(base string) (kind string, ok bool) {
+	switch base {
+	case "date", "dateTime", "time", "duration", "gYear":
+		return base, true
+	}
+	return "", false
+}
+
+// temporalTypeName returns the Go type generated for a temporal kind.
+func temporalTypeName(kind string) string {
+	switch kind {
+	case "date":
+		return "Date"
+	case "dateTime":
+		return "DateTime"
+	case "time":
+		return "Time"
+	case "duration":
+		return "Duration"
+	case "gYear":
+		return "Year"
+	}
+	panic(fmt.Sprintf("temporalTypeName: unknown kind %q", kind))
+}
+
+// usesTemporal reports whether e, or any of its attributes or
+// descendants, resolved to a temporal Go type.
+func usesTemporal(e *xmlElem) bool {
+	if e.Temporal != "" {
+		return true
+	}
+	for _, a := range e.Attribs {
+		if a.Temporal != "" {
+			return true
+		}
+	}
+	for _, c := range e.Children {
+		if usesTemporal(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// temporalTypesSource is the fixed set of wrapper types goxsd emits once
+// per output file whenever a schema uses one of the temporal primitives
+// (see usesTemporal). They wrap time.Time (or, for xsd:duration, its own
+// fields) with XSD-lexical-format Marshal/Unmarshal methods, since
+// encoding/xml cannot be told to format a plain time.Time field any other
+// way than RFC 3339.
+//
+// The layout constants are the one knob callers are expected to tweak by
+// hand if a schema's producer deviates from the XSD spec (e.g. omitting
+// the timezone on xsd:date values).
+const temporalTypesSource = `
+// dateLayout, dateTimeLayout, timeLayout and yearLayout are the xsd:date,
+// xsd:dateTime, xsd:time and xsd:gYear lexical layouts used to format and
+// parse Date, DateTime, Time and Year values.
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = time.RFC3339
+	timeLayout     = "15:04:05"
+	yearLayout     = "2006"
+)
+
+// Date wraps time.Time to (un)marshal as an xsd:date value.
+type Date struct {
+	time.Time
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(d.Time.Format(dateLayout), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *Date) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d Date) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.Time.Format(dateLayout)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *Date) UnmarshalXMLAttr(attr xml.Attr) error {
+	t, err := time.Parse(dateLayout, attr.Value)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// DateTime wraps time.Time to (un)marshal as an xsd:dateTime value.
+type DateTime struct {
+	time.Time
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d DateTime) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(d.Time.Format(dateTimeLayout), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *DateTime) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	t, err := time.Parse(dateTimeLayout, s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d DateTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.Time.Format(dateTimeLayout)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *DateTime) UnmarshalXMLAttr(attr xml.Attr) error {
+	t, err := time.Parse(dateTimeLayout, attr.Value)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// Time wraps time.Time to (un)marshal as an xsd:time value.
+type Time struct {
+	time.Time
+}
+
+// MarshalXML implements xml.Marshaler.
+func (t Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(t.Time.Format(timeLayout), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (t *Time) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (t Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: t.Time.Format(timeLayout)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
+	parsed, err := time.Parse(timeLayout, attr.Value)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Year wraps time.Time to (un)marshal as an xsd:gYear value.
+type Year struct {
+	time.Time
+}
+
+// MarshalXML implements xml.Marshaler.
+func (y Year) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(y.Time.Format(yearLayout), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (y *Year) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	t, err := time.Parse(yearLayout, s)
+	if err != nil {
+		return err
+	}
+	y.Time = t
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (y Year) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: y.Time.Format(yearLayout)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (y *Year) UnmarshalXMLAttr(attr xml.Attr) error {
+	t, err := time.Parse(yearLayout, attr.Value)
+	if err != nil {
+		return err
+	}
+	y.Time = t
+	return nil
+}
+
+// durationPattern matches the xsd:duration lexical form PnYnMnDTnHnMnS,
+// with every component optional except the leading P.
+var durationPattern = regexp.MustCompile(` + "`" + `^(-)?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:([\d.]+)S)?)?$` + "`" + `)
+
+// Duration represents an xsd:duration value.
+type Duration struct {
+	Negative bool
+	Years    int
+	Months   int
+	Days     int
+	Hours    int
+	Minutes  int
+	Seconds  float64
+}
+
+func (d Duration) format() string {
+	sign := ""
+	if d.Negative {
+		sign = "-"
+	}
+	s := sign + "P"
+	if d.Years != 0 {
+		s += fmt.Sprintf("%dY", d.Years)
+	}
+	if d.Months != 0 {
+		s += fmt.Sprintf("%dM", d.Months)
+	}
+	if d.Days != 0 {
+		s += fmt.Sprintf("%dD", d.Days)
+	}
+	if d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 {
+		s += "T"
+		if d.Hours != 0 {
+			s += fmt.Sprintf("%dH", d.Hours)
+		}
+		if d.Minutes != 0 {
+			s += fmt.Sprintf("%dM", d.Minutes)
+		}
+		if d.Seconds != 0 {
+			s += fmt.Sprintf("%gS", d.Seconds)
+		}
+	}
+	if s == sign+"P" {
+		s += "T0S"
+	}
+	return s
+}
+
+func parseDuration(s string) (Duration, error) {
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Duration{}, fmt.Errorf("invalid xsd:duration %q", s)
+	}
+	var d Duration
+	d.Negative = m[1] == "-"
+	d.Years = durationPart(m[2])
+	d.Months = durationPart(m[3])
+	d.Days = durationPart(m[4])
+	d.Hours = durationPart(m[5])
+	d.Minutes = durationPart(m[6])
+	if m[7] != "" {
+		secs, err := strconv.ParseFloat(m[7], 64)
+		if err != nil {
+			return d, fmt.Errorf("invalid xsd:duration %q: %w", s, err)
+		}
+		d.Seconds = secs
+	}
+	return d, nil
+}
+
+func durationPart(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d Duration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(d.format(), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *Duration) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	parsed, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+func (d Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: d.format()}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (d *Duration) UnmarshalXMLAttr(attr xml.Attr) error {
+	parsed, err := parseDuration(attr.Value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+`
@@ -17,16 +17,20 @@ type testCase struct {
 
 var (
 	tests = []struct {
-		exported bool
-		prefix   string
-		xsd      string
-		xml      xmlElem
-		gosrc    string
+		exported   bool
+		prefix     string
+		compact    bool
+		anyElement bool
+		xsd        string
+		xml        xmlElem
+		gosrc      string
 	}{
 
 		{
 			false, // Exported structs
 			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
 			`<schema>
 	<element name="titleList" type="titleListType">
 	</element>
@@ -60,17 +64,21 @@ var (
 	</complexType>
 </schema>`,
 			xmlElem{
-				Name: "titleList",
-				Type: "titleList",
+				Name:     "titleList",
+				Type:     "titleList",
+				TypeName: "titleListType",
+				Inline:   true,
 				Children: []*xmlElem{
 					&xmlElem{
-						Name:  "title",
-						Type:  "string",
-						Cdata: true,
-						List:  true,
+						Name:     "title",
+						Type:     "string",
+						TypeName: "originalTitleType",
+						Inline:   true,
+						Cdata:    true,
+						List:     true,
 						Attribs: []xmlAttrib{
-							{Name: "language", Type: "string"},
-							{Name: "original", Type: "bool"},
+							{Name: "language", Type: "string", Optional: true},
+							{Name: "original", Type: "bool", Optional: true},
 						},
 					},
 				},
@@ -92,6 +100,8 @@ type title struct {
 		{
 			false, // Exported structs
 			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
 			`<schema>
 	<element name="tagList">
 		<complexType>
@@ -118,14 +128,18 @@ type title struct {
 	</simpleType>
 </schema>`,
 			xmlElem{
-				Name: "tagList",
-				Type: "tagList",
+				Name:   "tagList",
+				Type:   "tagList",
+				Inline: true,
 				Children: []*xmlElem{
 					&xmlElem{
-						Name:  "tag",
-						Type:  "string",
-						List:  true,
-						Cdata: true,
+						Name:     "tag",
+						Type:     "string",
+						TypeName: "tagReferenceType",
+						Inline:   true,
+						List:     true,
+						Cdata:    true,
+						Optional: true,
 						Attribs: []xmlAttrib{
 							{Name: "type", Type: "string"},
 						},
@@ -147,6 +161,8 @@ type tag struct {
 		{
 			false, // Exported structs
 			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
 			`<schema>
 				<element name="tagId" type="tagReferenceType" />
 	<complexType name="tagReferenceType">
@@ -158,10 +174,12 @@ type tag struct {
 	</complexType>
 </schema>`,
 			xmlElem{
-				Name:  "tagId",
-				Type:  "string",
-				List:  false,
-				Cdata: true,
+				Name:     "tagId",
+				Type:     "string",
+				TypeName: "tagReferenceType",
+				Inline:   true,
+				List:     false,
+				Cdata:    true,
 				Attribs: []xmlAttrib{
 					{Name: "type", Type: "string"},
 				},
@@ -177,6 +195,8 @@ type tagID struct {
 		{
 			true,  // Exported structs
 			"xxx", // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
 			`<schema>
 	<element name="tag" type="tagReferenceType" />
 	<complexType name="tagReferenceType">
@@ -188,10 +208,12 @@ type tagID struct {
 	</complexType>
 </schema>`,
 			xmlElem{
-				Name:  "tag",
-				Type:  "string",
-				List:  false,
-				Cdata: true,
+				Name:     "tag",
+				Type:     "string",
+				TypeName: "tagReferenceType",
+				Inline:   true,
+				List:     false,
+				Cdata:    true,
 				Attribs: []xmlAttrib{
 					{Name: "type", Type: "string"},
 				},
@@ -203,49 +225,609 @@ type XxxTag struct {
 }
 			`,
 		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="tagRef" type="tagReferenceType" />
+	<complexType name="tagReferenceType">
+		<simpleContent>
+			<extension base="string">
+				<attribute name="type" type="tagTypeType" use="required" />
+			</extension>
+		</simpleContent>
+	</complexType>
+	<simpleType name="tagTypeType">
+		<restriction base="string">
+			<enumeration value="foo" />
+			<enumeration value="bar" />
+		</restriction>
+	</simpleType>
+</schema>`,
+			xmlElem{
+				Name:     "tagRef",
+				Type:     "string",
+				TypeName: "tagReferenceType",
+				Inline:   true,
+				Cdata:    true,
+				Attribs: []xmlAttrib{
+					{Name: "type", Type: "tagTypeType", Enum: []string{"foo", "bar"}, EnumBase: "string"},
+				},
+			},
+			`
+type tagRef struct {
+	Type tagTypeType ` + "`xml:\"type,attr\"`" + `
+	TagRef string ` + "`xml:\",chardata\"`" + `
+}
+
+type tagTypeType string
+
+const (
+	tagTypeTypeFoo tagTypeType = "foo"
+	tagTypeTypeBar tagTypeType = "bar"
+)
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			true,  // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="titleList" type="titleListType">
+	</element>
+	<complexType name="titleListType">
+		<sequence>
+			<element name="title" type="originalTitleType" maxOccurs="unbounded" />
+		</sequence>
+	</complexType>
+	<complexType name="originalTitleType">
+		<simpleContent>
+			<extension base="string">
+				<attribute name="original" type="boolean">
+				</attribute>
+			</extension>
+		</simpleContent>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "titleList",
+				Type:     "titleList",
+				TypeName: "titleListType",
+				Inline:   true,
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:     "title",
+						Type:     "string",
+						TypeName: "originalTitleType",
+						Inline:   true,
+						Cdata:    true,
+						List:     true,
+						Attribs: []xmlAttrib{
+							{Name: "original", Type: "bool", Optional: true},
+						},
+					},
+				},
+			},
+			`
+type titleList struct {
+	Title []struct {
+		Original bool ` + "`xml:\"original,attr\"`" + `
+		Title    string ` + "`xml:\",chardata\"`" + `
+	} ` + "`xml:\"title\"`" + `
+}
+			`,
+		},
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			true,  // Any-element mode
+			`<schema>
+	<element name="titleList" type="titleListType">
+	</element>
+	<complexType name="titleListType">
+		<sequence>
+			<element name="title" type="originalTitleType" maxOccurs="unbounded" />
+		</sequence>
+	</complexType>
+	<complexType name="originalTitleType">
+		<simpleContent>
+			<extension base="string">
+				<attribute name="original" type="boolean">
+				</attribute>
+			</extension>
+		</simpleContent>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "titleList",
+				Type:     "titleList",
+				TypeName: "titleListType",
+				Inline:   true,
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:     "title",
+						Type:     "string",
+						TypeName: "originalTitleType",
+						Inline:   true,
+						Cdata:    true,
+						List:     true,
+						Attribs: []xmlAttrib{
+							{Name: "original", Type: "bool", Optional: true},
+						},
+					},
+				},
+			},
+			`
+type titleList struct {
+	Title []title ` + "`xml:\"title\"`" + `
+	AnyElements []AnyXMLElement ` + "`xml:\",any\"`" + `
+}
+
+type title struct {
+	Original bool ` + "`xml:\"original,attr\"`" + `
+	Title    string ` + "`xml:\",chardata\"`" + `
+}
+
+type AnyXMLElement struct {
+	XMLName  xml.Name ` + "`xml:\",any\"`" + `
+	Attrs    []xml.Attr ` + "`xml:\",any,attr\"`" + `
+	Chardata string ` + "`xml:\",chardata\"`" + `
+	Children []AnyXMLElement ` + "`xml:\",any\"`" + `
+}
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="event" type="eventType" />
+	<complexType name="eventType">
+		<sequence>
+			<element name="occurredAt" type="dateTime" />
+			<element name="validOn" type="date" minOccurs="0" />
+			<element name="releaseYear" type="gYear" />
+		</sequence>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "event",
+				Type:     "event",
+				TypeName: "eventType",
+				Inline:   true,
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:     "occurredAt",
+						Type:     "DateTime",
+						Inline:   true,
+						Cdata:    true,
+						Temporal: "dateTime",
+					},
+					&xmlElem{
+						Name:     "validOn",
+						Type:     "Date",
+						Inline:   true,
+						Cdata:    true,
+						Optional: true,
+						Temporal: "date",
+					},
+					&xmlElem{
+						Name:     "releaseYear",
+						Type:     "Year",
+						Inline:   true,
+						Cdata:    true,
+						Temporal: "gYear",
+					},
+				},
+			},
+			`
+type event struct {
+	OccurredAt  DateTime ` + "`xml:\"occurredAt\"`" + `
+	ValidOn     Date     ` + "`xml:\"validOn,omitempty\"`" + `
+	ReleaseYear Year     ` + "`xml:\"releaseYear\"`" + `
+}
+			` + temporalTypesSource,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="book" type="bookType" />
+	<complexType name="bookType">
+		<sequence>
+			<element name="title" type="string" />
+		</sequence>
+		<attribute name="id" type="string" />
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "book",
+				Type:     "book",
+				TypeName: "bookType",
+				Inline:   true,
+				Attribs: []xmlAttrib{
+					{Name: "id", Type: "string", Optional: true},
+				},
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:   "title",
+						Type:   "string",
+						Inline: true,
+						Cdata:  true,
+					},
+				},
+			},
+			`
+type book struct {
+	ID    string ` + "`xml:\"id,attr\"`" + `
+	Title string ` + "`xml:\"title\"`" + `
+}
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			true,  // Compact mode
+			true,  // Any-element mode
+			`<schema>
+	<element name="book" type="bookType" />
+	<complexType name="bookType">
+		<sequence>
+			<element name="detail" type="detailType" />
+		</sequence>
+	</complexType>
+	<complexType name="detailType">
+		<sequence>
+			<element name="summary" type="string" />
+		</sequence>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "book",
+				Type:     "book",
+				TypeName: "bookType",
+				Inline:   true,
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:     "detail",
+						Type:     "detail",
+						TypeName: "detailType",
+						Inline:   true,
+						Children: []*xmlElem{
+							&xmlElem{
+								Name:   "summary",
+								Type:   "string",
+								Inline: true,
+								Cdata:  true,
+							},
+						},
+					},
+				},
+			},
+			`
+type book struct {
+	Detail struct {
+		Summary string ` + "`xml:\"summary\"`" + `
+		AnyElements []AnyXMLElement ` + "`xml:\",any\"`" + `
+	} ` + "`xml:\"detail\"`" + `
+	AnyElements []AnyXMLElement ` + "`xml:\",any\"`" + `
+}
+
+type AnyXMLElement struct {
+	XMLName  xml.Name ` + "`xml:\",any\"`" + `
+	Attrs    []xml.Attr ` + "`xml:\",any,attr\"`" + `
+	Chardata string ` + "`xml:\",chardata\"`" + `
+	Children []AnyXMLElement ` + "`xml:\",any\"`" + `
+}
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="item" type="itemType" />
+	<complexType name="itemType">
+		<simpleContent>
+			<extension base="string">
+				<attribute name="level" type="levelType" use="required" />
+			</extension>
+		</simpleContent>
+	</complexType>
+	<simpleType name="levelType">
+		<restriction base="integer">
+			<enumeration value="1" />
+			<enumeration value="2" />
+		</restriction>
+	</simpleType>
+</schema>`,
+			xmlElem{
+				Name:     "item",
+				Type:     "string",
+				TypeName: "itemType",
+				Inline:   true,
+				Cdata:    true,
+				Attribs: []xmlAttrib{
+					{Name: "level", Type: "levelType", Enum: []string{"1", "2"}, EnumBase: "int"},
+				},
+			},
+			`
+type item struct {
+	Level levelType ` + "`xml:\"level,attr\"`" + `
+	Item  string    ` + "`xml:\",chardata\"`" + `
+}
+
+type levelType int
+
+const (
+	levelType1 levelType = 1
+	levelType2 levelType = 2
+)
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="book" type="bookType" />
+	<complexType name="bookType">
+		<sequence>
+			<element name="level">
+				<simpleType>
+					<restriction base="string">
+						<enumeration value="low" />
+						<enumeration value="high" />
+					</restriction>
+				</simpleType>
+			</element>
+		</sequence>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "book",
+				Type:     "book",
+				TypeName: "bookType",
+				Inline:   true,
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:     "level",
+						Type:     "level",
+						Inline:   true,
+						Cdata:    true,
+						Enum:     []string{"low", "high"},
+						EnumBase: "string",
+					},
+				},
+			},
+			`
+type book struct {
+	Level level ` + "`xml:\"level\"`" + `
+}
+
+type level string
+
+const (
+	levelLow  level = "low"
+	levelHigh level = "high"
+)
+			`,
+		},
+
+		{
+			false, // Exported structs
+			"",    // Struct prefix
+			false, // Compact mode
+			false, // Any-element mode
+			`<schema>
+	<element name="book" type="bookType" />
+	<complexType name="bookType">
+		<sequence>
+			<element name="title" type="string" />
+		</sequence>
+		<attribute name="status">
+			<simpleType>
+				<restriction base="string">
+					<enumeration value="active" />
+					<enumeration value="retired" />
+				</restriction>
+			</simpleType>
+		</attribute>
+	</complexType>
+</schema>`,
+			xmlElem{
+				Name:     "book",
+				Type:     "book",
+				TypeName: "bookType",
+				Inline:   true,
+				Attribs: []xmlAttrib{
+					{Name: "status", Type: "status", Optional: true, Enum: []string{"active", "retired"}, EnumBase: "string"},
+				},
+				Children: []*xmlElem{
+					&xmlElem{
+						Name:   "title",
+						Type:   "string",
+						Inline: true,
+						Cdata:  true,
+					},
+				},
+			},
+			`
+type book struct {
+	Status status ` + "`xml:\"status,attr\"`" + `
+	Title  string ` + "`xml:\"title\"`" + `
+}
+
+type status string
+
+const (
+	statusActive  status = "active"
+	statusRetired status = "retired"
+)
+			`,
+		},
 	}
 )
 
 func reset() {
-	exported = false
-	prefix = ""
-	types = make(map[string]struct{})
+	nameFunc = LowercaseNameFunc
+	compact = false
+	anyElement = false
+	anyElementType = "AnyXMLElement"
+	resetGenerationState()
+}
+
+// testNameFunc reproduces the NameFunc selection main.go used to make from
+// the original -e/-p flags, so the table tests can keep driving naming via
+// plain exported/prefix fields.
+func testNameFunc(exported bool, prefix string) NameFunc {
+	switch {
+	case prefix != "":
+		return PrefixNameFunc(prefix)
+	case exported:
+		return ExportedNameFunc
+	default:
+		return LowercaseNameFunc
+	}
 }
 
 func removeComments(buf bytes.Buffer) bytes.Buffer {
 	lines := strings.Split(buf.String(), "\n")
-	for i, l := range lines {
-		if strings.HasPrefix(l, "//") {
-			lines = append(lines[:i], lines[i+1:]...)
+	kept := lines[:0]
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "//") {
+			kept = append(kept, l)
 		}
 	}
-	return *bytes.NewBufferString(strings.Join(lines, "\n"))
+	return *bytes.NewBufferString(strings.Join(kept, "\n"))
 }
 
 func TestGenerateGo(t *testing.T) {
 	for _, tst := range tests {
 		reset()
-		exported = tst.exported
-		prefix = tst.prefix
+		nameFunc = testNameFunc(tst.exported, tst.prefix)
+		compact = tst.compact
+		anyElement = tst.anyElement
 		var out bytes.Buffer
 		doGenerate(&tst.xml, &out)
 		out = removeComments(out)
-		if strings.Join(strings.Fields(out.String()), "") != strings.Join(strings.Fields(tst.gosrc), "") {
+		want := removeComments(*bytes.NewBufferString(tst.gosrc))
+		if strings.Join(strings.Fields(out.String()), "") != strings.Join(strings.Fields(want.String()), "") {
 			t.Errorf("Unexpected generated Go source: %s", tst.xml.Name)
 			t.Logf(out.String())
 			t.Logf(strings.Join(strings.Fields(out.String()), ""))
-			t.Logf(strings.Join(strings.Fields(tst.gosrc), ""))
+			t.Logf(strings.Join(strings.Fields(want.String()), ""))
 		}
 	}
 }
 
+// TestGenerateGoDedupsAcrossRoots guards against a bug where doGenerate
+// reset the types dedup map on every call: a schema with several
+// top-level elements that share a same-named descendant (here "detail",
+// referenced by both bookType and movieType) must only emit that
+// descendant's struct once across the whole output, the same way
+// main.go's single doGenerate loop relies on.
+func TestGenerateGoDedupsAcrossRoots(t *testing.T) {
+	reset()
+	schema, err := extract(bytes.NewBufferString(`<schema>
+	<element name="book" type="bookType" />
+	<element name="movie" type="movieType" />
+	<complexType name="bookType">
+		<sequence>
+			<element name="detail" type="detailType" />
+		</sequence>
+	</complexType>
+	<complexType name="movieType">
+		<sequence>
+			<element name="detail" type="detailType" />
+		</sequence>
+	</complexType>
+	<complexType name="detailType">
+		<sequence>
+			<element name="summary" type="string" />
+		</sequence>
+	</complexType>
+</schema>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newBuilder([]xsdSchema{schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elems := b.buildXML()
+	if len(elems) != 2 {
+		t.Fatalf("wrong number of xml elements: %d", len(elems))
+	}
+
+	var out bytes.Buffer
+	resetGenerationState()
+	for _, e := range elems {
+		doGenerate(e, &out)
+	}
+
+	if n := strings.Count(out.String(), "type detail struct"); n != 1 {
+		t.Errorf("expected detail struct to be emitted once across roots, got %d:\n%s", n, out.String())
+	}
+}
+
+// TestNewBuilderErrorsOnDuplicateTypeName guards the flat, namespace-blind
+// type table: two schemas (as xs:import commonly produces) that declare
+// the same local complexType name must be rejected rather than having
+// the later one silently win.
+func TestNewBuilderErrorsOnDuplicateTypeName(t *testing.T) {
+	a, err := extract(bytes.NewBufferString(`<schema>
+	<complexType name="itemType">
+		<sequence>
+			<element name="fromA" type="string" />
+		</sequence>
+	</complexType>
+</schema>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := extract(bytes.NewBufferString(`<schema>
+	<complexType name="itemType">
+		<sequence>
+			<element name="fromB" type="string" />
+		</sequence>
+	</complexType>
+</schema>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newBuilder([]xsdSchema{a, b}); err == nil {
+		t.Fatal("expected an error for a complexType name declared in two schemas, got nil")
+	}
+}
+
 func TestBuildXmlElem(t *testing.T) {
 	for _, tst := range tests {
 		schema, err := extract(bytes.NewBufferString(tst.xsd))
 		if err != nil {
 			t.Error(err)
 		}
-		b := newBuilder([]xsdSchema{schema})
+		b, err := newBuilder([]xsdSchema{schema})
+		if err != nil {
+			t.Fatal(err)
+		}
 		elems := b.buildXML()
 		if len(elems) != 1 {
 			t.Errorf("wrong number of xml elements")
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xsdSchema is the root of a parsed XSD document.
+type xsdSchema struct {
+	XMLName      xml.Name         `xml:"schema"`
+	Includes     []xsdInclude     `xml:"include"`
+	Imports      []xsdImport      `xml:"import"`
+	Elements     []xsdElement     `xml:"element"`
+	ComplexTypes []xsdComplexType `xml:"complexType"`
+	SimpleTypes  []xsdSimpleType  `xml:"simpleType"`
+}
+
+// xsdInclude models <xs:include schemaLocation="..."/>: it pulls another
+// schema document's declarations into the same target namespace.
+type xsdInclude struct {
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+// xsdImport models <xs:import namespace="..." schemaLocation="..."/>: it
+// makes declarations from another namespace available for reference.
+type xsdImport struct {
+	Namespace      string `xml:"namespace,attr"`
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+// xsdElement models an <xs:element> declaration, either a top-level named
+// element or one embedded inside a complexType's sequence.
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	MinOccurs   string          `xml:"minOccurs,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+	SimpleType  *xsdSimpleType  `xml:"simpleType"`
+}
+
+// list reports whether the element can occur more than once.
+func (e xsdElement) list() bool {
+	return e.MaxOccurs == "unbounded"
+}
+
+// xsdComplexType models an <xs:complexType>, named at the top level or
+// declared anonymously inside an element.
+type xsdComplexType struct {
+	Name          string            `xml:"name,attr"`
+	Sequence      []xsdElement      `xml:"sequence>element"`
+	SimpleContent *xsdSimpleContent `xml:"simpleContent"`
+	Attributes    []xsdAttribute    `xml:"attribute"`
+}
+
+// xsdSimpleContent models the <xs:simpleContent> of a complexType that
+// carries character data alongside attributes.
+type xsdSimpleContent struct {
+	Extension   *xsdExtension   `xml:"extension"`
+	Restriction *xsdRestriction `xml:"restriction"`
+}
+
+// xsdExtension models <xs:extension base="...">, optionally adding
+// attributes on top of its base type.
+type xsdExtension struct {
+	Base       string         `xml:"base,attr"`
+	Attributes []xsdAttribute `xml:"attribute"`
+}
+
+// xsdRestriction models <xs:restriction base="...">, optionally narrowing
+// the base type to a fixed set of <xs:enumeration> values.
+type xsdRestriction struct {
+	Base        string           `xml:"base,attr"`
+	Enumeration []xsdEnumeration `xml:"enumeration"`
+}
+
+// xsdEnumeration models a single <xs:enumeration value="..."/> member of a
+// restriction.
+type xsdEnumeration struct {
+	Value string `xml:"value,attr"`
+}
+
+// xsdSimpleType models a top-level or inline <xs:simpleType>.
+type xsdSimpleType struct {
+	Name        string          `xml:"name,attr"`
+	Restriction *xsdRestriction `xml:"restriction"`
+}
+
+// xsdAttribute models an <xs:attribute> declaration, either typed by
+// reference (Type) or by an inline, anonymous SimpleType.
+type xsdAttribute struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Use        string         `xml:"use,attr"`
+	SimpleType *xsdSimpleType `xml:"simpleType"`
+}
+
+// extract parses a single XSD document into an xsdSchema.
+func extract(r io.Reader) (xsdSchema, error) {
+	var schema xsdSchema
+	if err := xml.NewDecoder(r).Decode(&schema); err != nil {
+		return xsdSchema{}, err
+	}
+	return schema, nil
+}